@@ -0,0 +1,253 @@
+/*
+Copyright 2023 The Perses Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// Metadata holds the common labels/annotations that get propagated onto the
+// child resources (Service, Deployment, ConfigMap, ...) owned by a Perses CR.
+type Metadata struct {
+	// Labels to add to every resource created for this Perses instance.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations to add to every resource created for this Perses instance.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// ServicePort describes a single port exposed by the Perses Service. It
+// mirrors corev1.ServicePort, trimmed down to the fields that make sense to
+// let users configure.
+type ServicePort struct {
+	// Name of this port within the Service. Must be unique.
+	Name string `json:"name"`
+
+	// Port is the port that is exposed by the Service.
+	Port int32 `json:"port"`
+
+	// TargetPort is the port the Perses container listens on. Defaults to
+	// Port when unset.
+	// +optional
+	TargetPort intstr.IntOrString `json:"targetPort,omitempty"`
+
+	// Protocol for this port. Defaults to TCP.
+	// +optional
+	Protocol corev1.Protocol `json:"protocol,omitempty"`
+
+	// AppProtocol, as defined in corev1.ServicePort.
+	// +optional
+	AppProtocol *string `json:"appProtocol,omitempty"`
+
+	// NodePort is the port exposed on each node when Type is NodePort or
+	// LoadBalancer. Left to the cluster to assign when unset.
+	// +optional
+	NodePort int32 `json:"nodePort,omitempty"`
+}
+
+// ServiceSpec lets users customize the Service created for a Perses
+// instance: its type, the ports it exposes and a handful of
+// Service-specific knobs that only make sense for certain types
+// (LoadBalancer, headless, ...).
+type ServiceSpec struct {
+	// Type of Service to create. Defaults to ClusterIP. For a headless
+	// Service (ClusterIP: None), leave Type as ClusterIP and set
+	// HeadlessService below.
+	// +kubebuilder:validation:Enum=ClusterIP;NodePort;LoadBalancer;ExternalName
+	// +optional
+	Type corev1.ServiceType `json:"type,omitempty"`
+
+	// HeadlessService requests a headless Service (ClusterIP: None) instead
+	// of a cluster-assigned ClusterIP. Ignored unless Type is ClusterIP.
+	// +optional
+	HeadlessService bool `json:"headlessService,omitempty"`
+
+	// Ports exposed by the Service. At least one port must target the
+	// Perses HTTP container port.
+	// +optional
+	Ports []ServicePort `json:"ports,omitempty"`
+
+	// LoadBalancerIP requests a specific load balancer IP. Only honored
+	// when Type is LoadBalancer, and only by cloud providers that support it.
+	// +optional
+	LoadBalancerIP string `json:"loadBalancerIP,omitempty"`
+
+	// LoadBalancerSourceRanges restricts traffic through the load balancer
+	// to the given client IPs. Only honored when Type is LoadBalancer.
+	// +optional
+	LoadBalancerSourceRanges []string `json:"loadBalancerSourceRanges,omitempty"`
+
+	// ExternalTrafficPolicy denotes whether traffic should be routed to
+	// node-local or cluster-wide endpoints.
+	// +optional
+	ExternalTrafficPolicy corev1.ServiceExternalTrafficPolicy `json:"externalTrafficPolicy,omitempty"`
+
+	// SessionAffinity used to maintain session affinity.
+	// +optional
+	SessionAffinity corev1.ServiceAffinity `json:"sessionAffinity,omitempty"`
+
+	// IPFamilyPolicy controls whether the Service is single or dual stack.
+	// +optional
+	IPFamilyPolicy *corev1.IPFamilyPolicy `json:"ipFamilyPolicy,omitempty"`
+}
+
+// PersesSpec defines the desired state of Perses
+type PersesSpec struct {
+	// Replicas is the number of desired Perses pods. Defaults to 1.
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Metadata holds labels/annotations to apply to every child resource.
+	// +optional
+	Metadata *Metadata `json:"metadata,omitempty"`
+
+	// Service customizes the Service fronting the Perses deployment.
+	// +optional
+	Service *ServiceSpec `json:"service,omitempty"`
+
+	// Monitoring configures how this Perses instance integrates with
+	// Prometheus-style monitoring.
+	// +optional
+	Monitoring *MonitoringSpec `json:"monitoring,omitempty"`
+
+	// TLS configures HTTPS for this Perses instance. When enabled, the
+	// Service gets an additional https port, the ServiceMonitor (if any)
+	// scrapes that port, and reconcileTLS validates or requests SecretName
+	// and reports the TLSReady condition. No Ingress TLS stanza is managed.
+	// +optional
+	TLS *TLSSpec `json:"tls,omitempty"`
+}
+
+// TLSEnabled reports whether spec requests TLS termination at the Perses
+// Service.
+func (s *PersesSpec) TLSEnabled() bool {
+	return s != nil && s.TLS != nil && s.TLS.SecretName != ""
+}
+
+// TLSSpec configures HTTPS for a Perses instance. Set either
+// CertManagerIssuerRef (to have the operator request and manage the
+// certificate) or bring your own pre-existing Secret named SecretName.
+type TLSSpec struct {
+	// SecretName is the Secret holding the tls.crt/tls.key pair used to
+	// serve HTTPS. Required whenever TLS is enabled.
+	SecretName string `json:"secretName"`
+
+	// CertManagerIssuerRef, when set, causes the operator to request
+	// SecretName from cert-manager using this Issuer/ClusterIssuer. When
+	// unset, the operator expects SecretName to already exist and only
+	// validates its keys.
+	// +optional
+	CertManagerIssuerRef *cmmeta.ObjectReference `json:"certManagerIssuerRef,omitempty"`
+
+	// MinVersion is the minimum TLS version to accept. Its value is
+	// validated against the enum below, but the operator does not apply it
+	// to the Perses listener config.
+	// +kubebuilder:validation:Enum=1.0;1.1;1.2;1.3
+	// +optional
+	MinVersion string `json:"minVersion,omitempty"`
+}
+
+// MonitoringSpec configures Prometheus-style monitoring for a Perses
+// instance.
+type MonitoringSpec struct {
+	// ServiceMonitor, when set with Enabled true, causes the operator to
+	// create a monitoring.coreos.com/v1 ServiceMonitor selecting this
+	// instance's Service. Requires the ServiceMonitor CRD to be installed in the
+	// cluster.
+	// +optional
+	ServiceMonitor *ServiceMonitorSpec `json:"serviceMonitor,omitempty"`
+}
+
+// ServiceMonitorSpec configures the ServiceMonitor created for a Perses
+// instance.
+type ServiceMonitorSpec struct {
+	// Enabled creates the ServiceMonitor. Defaults to false.
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// Interval at which Prometheus should scrape this instance.
+	// +optional
+	Interval monitoringv1.Duration `json:"interval,omitempty"`
+
+	// ScrapeTimeout for each scrape of this instance.
+	// +optional
+	ScrapeTimeout monitoringv1.Duration `json:"scrapeTimeout,omitempty"`
+
+	// TLSConfig to use when scraping over https.
+	// +optional
+	TLSConfig *monitoringv1.TLSConfig `json:"tlsConfig,omitempty"`
+
+	// BearerTokenSecret, when set, is used to authenticate scrapes.
+	// +optional
+	BearerTokenSecret *corev1.SecretKeySelector `json:"bearerTokenSecret,omitempty"`
+
+	// RelabelConfigs applied to samples before scraping.
+	// +optional
+	RelabelConfigs []monitoringv1.RelabelConfig `json:"relabelings,omitempty"`
+}
+
+// GetServiceMonitor returns m.ServiceMonitor, tolerating a nil receiver so
+// callers don't need to nil-check Spec.Monitoring themselves.
+func (m *MonitoringSpec) GetServiceMonitor() *ServiceMonitorSpec {
+	if m == nil {
+		return nil
+	}
+	return m.ServiceMonitor
+}
+
+// PersesStatus defines the observed state of Perses
+type PersesStatus struct {
+	// Conditions store the status conditions of the Perses instance.
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// Perses is the Schema for the perses API
+type Perses struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PersesSpec   `json:"spec,omitempty"`
+	Status PersesStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// PersesList contains a list of Perses
+type PersesList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Perses `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Perses{}, &PersesList{})
+}
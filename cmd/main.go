@@ -0,0 +1,182 @@
+/*
+Copyright 2023 The Perses Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+
+	"github.com/perses/perses-operator/api/v1alpha1"
+	"github.com/perses/perses-operator/controllers/perses"
+	"github.com/perses/perses-operator/internal/logging"
+	"github.com/perses/perses-operator/internal/perses/metrics"
+)
+
+var (
+	scheme = runtime.NewScheme()
+)
+
+func init() {
+	utilruntimeMust(clientgoscheme.AddToScheme(scheme))
+	utilruntimeMust(v1alpha1.AddToScheme(scheme))
+	utilruntimeMust(monitoringv1.AddToScheme(scheme))
+	utilruntimeMust(cmapi.AddToScheme(scheme))
+}
+
+func utilruntimeMust(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+// metricsPort extracts the numeric port from a "--metrics-bind-address"
+// value like ":8443", so the operator-metrics Service always targets
+// whatever port controller-runtime's metrics server is actually listening
+// on instead of a literal that can drift from it.
+func metricsPort(addr string) (int32, error) {
+	_, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return 0, err
+	}
+
+	port, err := strconv.ParseInt(portStr, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid metrics port %q: %w", portStr, err)
+	}
+	return int32(port), nil
+}
+
+func main() {
+	var metricsAddr string
+	var probeAddr string
+	var persesImage string
+	var enableLeaderElection bool
+	var watchNamespaces string
+	var persesSelector string
+
+	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8443", "The address the metrics endpoint binds to.")
+	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
+	flag.StringVar(&persesImage, "perses-image", "persesdev/perses:latest", "The default Perses image to deploy.")
+	flag.BoolVar(&enableLeaderElection, "leader-elect", false, "Enable leader election for controller manager.")
+	flag.StringVar(&watchNamespaces, "watch-namespaces", "", "Comma-separated list of namespaces to watch. Watches all namespaces when empty.")
+	flag.StringVar(&persesSelector, "perses-selector", "", "Label selector restricting which Perses CRs this operator instance reconciles. Reconciles all Perses CRs when empty.")
+	flag.Parse()
+
+	rootLog := logging.New()
+	ctrl.SetLogger(rootLog)
+
+	selector, err := labels.Parse(persesSelector)
+	if err != nil {
+		ctrl.Log.Error(err, "invalid --perses-selector")
+		os.Exit(1)
+	}
+
+	cacheOpts := cache.Options{}
+	if watchNamespaces != "" {
+		defaultNamespaces := map[string]cache.Config{}
+		for _, ns := range strings.Split(watchNamespaces, ",") {
+			defaultNamespaces[strings.TrimSpace(ns)] = cache.Config{}
+		}
+		cacheOpts.DefaultNamespaces = defaultNamespaces
+	}
+
+	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+		Scheme:                 scheme,
+		Metrics:                metricsserver.Options{BindAddress: metricsAddr},
+		HealthProbeBindAddress: probeAddr,
+		LeaderElection:         enableLeaderElection,
+		LeaderElectionID:       "perses-operator-lock",
+		Cache:                  cacheOpts,
+	})
+	if err != nil {
+		ctrl.Log.Error(err, "unable to start manager")
+		os.Exit(1)
+	}
+
+	reconciler := &perses.PersesReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+		Config: perses.OperatorConfig{PersesImage: persesImage, Selector: selector},
+		Log:    rootLog.WithName("controllers").WithName("Perses"),
+	}
+	if err = reconciler.SetupWithManager(mgr); err != nil {
+		ctrl.Log.Error(err, "unable to create controller", "controller", "Perses")
+		os.Exit(1)
+	}
+
+	operatorNamespace := os.Getenv("OPERATOR_NAMESPACE")
+	if operatorNamespace != "" {
+		operatorLabels := map[string]string{"app.kubernetes.io/name": "perses-operator"}
+
+		// metricsAddr "0" is controller-runtime's convention for "don't
+		// serve metrics at all"; there's nothing to front with a Service
+		// then, so skip without treating it as an error. Any other
+		// unparsable value is logged and skipped as well, rather than
+		// failing the whole operator over an ancillary metrics Service.
+		if metricsSvcPort, err := metricsPort(metricsAddr); err != nil {
+			if metricsAddr != "0" {
+				ctrl.Log.Error(err, "invalid --metrics-bind-address, skipping operator-metrics Service/ServiceMonitor")
+			}
+		} else {
+			// A direct, uncached client: mgr.GetClient() reads through the
+			// manager's informer cache, while this runnable's reads and
+			// writes go straight to the API server.
+			metricsClient, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme()})
+			if err != nil {
+				ctrl.Log.Error(err, "unable to create client for operator metrics")
+				os.Exit(1)
+			}
+
+			operatorMetrics := metrics.RunOperatorMetrics(metricsClient, operatorNamespace, operatorLabels, metricsSvcPort,
+				rootLog.WithName("operator-metrics"))
+			if err := mgr.Add(operatorMetrics); err != nil {
+				ctrl.Log.Error(err, "unable to add operator metrics runnable")
+				os.Exit(1)
+			}
+		}
+	}
+
+	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
+		ctrl.Log.Error(err, "unable to set up health check")
+		os.Exit(1)
+	}
+	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
+		ctrl.Log.Error(err, "unable to set up ready check")
+		os.Exit(1)
+	}
+
+	ctrl.Log.Info("starting manager")
+	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+		ctrl.Log.Error(err, "problem running manager")
+		os.Exit(1)
+	}
+}
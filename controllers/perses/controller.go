@@ -0,0 +1,141 @@
+/*
+Copyright 2023 The Perses Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package perses
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/perses/perses-operator/api/v1alpha1"
+	"github.com/perses/perses-operator/internal/subreconciler"
+)
+
+// OperatorConfig carries the operator-wide settings that subreconcilers
+// need when rendering child resources (e.g. which Perses image to deploy).
+type OperatorConfig struct {
+	PersesImage string
+
+	// Selector, when non-nil and non-empty, restricts this operator
+	// instance to Perses CRs whose labels match it. This lets several
+	// Perses operator installs coexist in one cluster (e.g. split by
+	// team) via `--perses-selector`.
+	Selector labels.Selector
+}
+
+// matchesSelector reports whether obj should be reconciled by this
+// operator instance, given OperatorConfig.Selector.
+func (r *PersesReconciler) matchesSelector(objLabels map[string]string) bool {
+	if r.Config.Selector == nil || r.Config.Selector.Empty() {
+		return true
+	}
+	return r.Config.Selector.Matches(labels.Set(objLabels))
+}
+
+// PersesReconciler reconciles a Perses object
+type PersesReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	Config OperatorConfig
+
+	// Log is the root logger each subreconciler scopes with
+	// perses/namespace/reconciler fields before using it.
+	Log logr.Logger
+}
+
+// +kubebuilder:rbac:groups=perses.dev,resources=perses,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=perses.dev,resources=perses/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=perses.dev,resources=perses/finalizers,verbs=update
+// +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile drives a Perses instance towards its desired state by running
+// each subreconciler in turn, stopping as soon as one of them asks to halt
+// or requeue.
+//
+// reconcileTLS runs last: it requeues on every reconcile where the
+// Certificate/Secret isn't Ready yet, and the Service (which already opens
+// the https port and must come up over plain HTTP in the meantime) must not
+// wait on that.
+func (r *PersesReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	subreconcilersForPerses := []func(context.Context, ctrl.Request) (*ctrl.Result, error){
+		r.reconcileService,
+		r.reconcileServiceMonitor,
+		r.reconcileTLS,
+	}
+
+	for _, subreconciler := range subreconcilersForPerses {
+		if result, err := subreconciler(ctx, req); result != nil || err != nil {
+			if err != nil {
+				return *result, err
+			}
+			return *result, nil
+		}
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// getLatestPerses fetches the Perses instance named in req into perses,
+// returning a result/error pair the caller should immediately return
+// whenever it is non-nil.
+func (r *PersesReconciler) getLatestPerses(ctx context.Context, req ctrl.Request, perses *v1alpha1.Perses) (*ctrl.Result, error) {
+	if err := r.Get(ctx, req.NamespacedName, perses); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.Log.Info("Perses resource not found. Ignoring since object must be deleted", "perses", req.NamespacedName)
+			return subreconciler.DoNotRequeue()
+		}
+
+		r.Log.Error(err, "Failed to get Perses", "perses", req.NamespacedName)
+		return subreconciler.RequeueWithError(err)
+	}
+
+	if !r.matchesSelector(perses.Labels) {
+		r.Log.Info("Perses does not match --perses-selector, skipping", "perses", req.NamespacedName)
+		return subreconciler.DoNotRequeue()
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+// SetupWithManager sets up the controller with the Manager. Owning each
+// child resource type means a manual `kubectl delete` on one of them
+// (instead of the parent Perses CR) still generates a watch event that
+// re-triggers the matching subreconciler immediately, rather than waiting
+// for the next full resync.
+func (r *PersesReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	selectorPredicate := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return r.matchesSelector(obj.GetLabels())
+	})
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.Perses{}, builder.WithPredicates(selectorPredicate)).
+		Owns(&corev1.Service{}).
+		Owns(&appsv1.Deployment{}).
+		Owns(&networkingv1.Ingress{}).
+		Owns(&corev1.ConfigMap{}).
+		Complete(r)
+}
@@ -20,7 +20,6 @@ import (
 	"context"
 	"fmt"
 
-	logger "github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -36,8 +35,6 @@ import (
 	"github.com/perses/perses-operator/internal/subreconciler"
 )
 
-var slog = logger.WithField("module", "service_controller")
-
 func (r *PersesReconciler) reconcileService(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
 	perses := &v1alpha1.Perses{}
 
@@ -45,33 +42,35 @@ func (r *PersesReconciler) reconcileService(ctx context.Context, req ctrl.Reques
 		return result, err
 	}
 
+	log := r.Log.WithValues("perses", perses.Name, "namespace", perses.Namespace, "reconciler", "service")
+
 	found := &corev1.Service{}
 	if err := r.Get(ctx, types.NamespacedName{Name: perses.Name, Namespace: perses.Namespace}, found); err != nil {
 		if !apierrors.IsNotFound(err) {
-			log.WithError(err).Error("Failed to get Service")
+			log.Error(err, "Failed to get Service")
 
 			return subreconciler.RequeueWithError(err)
 		}
 
 		ser, err2 := r.createPersesService(perses)
 		if err2 != nil {
-			slog.WithError(err2).Error("Failed to define new Service resource for perses")
+			log.Error(err2, "Failed to define new Service resource for perses")
 
 			meta.SetStatusCondition(&perses.Status.Conditions, metav1.Condition{Type: common.TypeAvailablePerses,
 				Status: metav1.ConditionFalse, Reason: "Reconciling",
 				Message: fmt.Sprintf("Failed to create Service for the custom resource (%s): (%s)", perses.Name, err2)})
 
 			if err = r.Status().Update(ctx, perses); err != nil {
-				slog.Error(err, "Failed to update perses status")
+				log.Error(err, "Failed to update perses status")
 				return subreconciler.RequeueWithError(err)
 			}
 
 			return subreconciler.RequeueWithError(err2)
 		}
 
-		slog.Infof("Creating a new Service: Service.Namespace %s Service.Name %s", ser.Namespace, ser.Name)
+		log.Info("Creating a new Service", "Service.Namespace", ser.Namespace, "Service.Name", ser.Name)
 		if err = r.Create(ctx, ser); err != nil {
-			slog.WithError(err).Errorf("Failed to create new Service: Service.Namespace %s Service.Name %s", ser.Namespace, ser.Name)
+			log.Error(err, "Failed to create new Service", "Service.Namespace", ser.Namespace, "Service.Name", ser.Name)
 			return subreconciler.RequeueWithError(err)
 		}
 
@@ -80,19 +79,34 @@ func (r *PersesReconciler) reconcileService(ctx context.Context, req ctrl.Reques
 
 	svc, err := r.createPersesService(perses)
 	if err != nil {
-		slog.WithError(err).Error("Failed to define new Service resource for perses")
+		log.Error(err, "Failed to define new Service resource for perses")
+
+		meta.SetStatusCondition(&perses.Status.Conditions, metav1.Condition{Type: common.TypeDegradedPerses,
+			Status: metav1.ConditionTrue, Reason: "ServiceSpecRejected",
+			Message: fmt.Sprintf("Requested Service configuration for (%s) was rejected: (%s)", perses.Name, err)})
+
+		if statusErr := r.Status().Update(ctx, perses); statusErr != nil {
+			log.Error(statusErr, "Failed to update perses status")
+			return subreconciler.RequeueWithError(statusErr)
+		}
+
 		return subreconciler.RequeueWithError(err)
 	}
 
+	// Preserve the cluster-assigned fields the API server fills in on create
+	// (ClusterIP(s), per-port NodePort) so that recomputing the desired
+	// Service spec on every reconcile doesn't flap them.
+	preserveClusterAssignedFields(found, svc)
+
 	// call update with dry run to fill out fields that are also returned via the k8s api
 	if err = r.Update(ctx, svc, client.DryRunAll); err != nil {
-		slog.Error(err, "Failed to update Service with dry run")
+		log.Error(err, "Failed to update Service with dry run")
 		return subreconciler.RequeueWithError(err)
 	}
 
 	if !equality.Semantic.DeepEqual(found, svc) {
 		if err = r.Update(ctx, svc); err != nil {
-			slog.Error(err, "Failed to update Service")
+			log.Error(err, "Failed to update Service")
 			return subreconciler.RequeueWithError(err)
 		}
 	}
@@ -100,6 +114,37 @@ func (r *PersesReconciler) reconcileService(ctx context.Context, req ctrl.Reques
 	return subreconciler.ContinueReconciling()
 }
 
+// preserveClusterAssignedFields copies the Service fields that the API
+// server owns (and that users can't express in PersesSpec.Service) from the
+// currently-live Service onto the freshly-rendered one, so reconciling
+// doesn't try to clear a ClusterIP or NodePort the cluster already assigned.
+func preserveClusterAssignedFields(found, desired *corev1.Service) {
+	// Don't copy the old ClusterIP onto a Service that buildServiceSpec just
+	// rendered as headless (ClusterIP: None) - that's a user-requested
+	// change, not one the cluster owns, and copying it over would silently
+	// revert HeadlessService back to the previously assigned ClusterIP on
+	// every reconcile.
+	if found.Spec.Type != corev1.ServiceTypeExternalName && desired.Spec.ClusterIP != corev1.ClusterIPNone {
+		desired.Spec.ClusterIP = found.Spec.ClusterIP
+		desired.Spec.ClusterIPs = found.Spec.ClusterIPs
+	}
+
+	foundPortsByName := make(map[string]corev1.ServicePort, len(found.Spec.Ports))
+	for _, p := range found.Spec.Ports {
+		foundPortsByName[p.Name] = p
+	}
+
+	for i := range desired.Spec.Ports {
+		existing, ok := foundPortsByName[desired.Spec.Ports[i].Name]
+		if ok && desired.Spec.Ports[i].NodePort == 0 {
+			desired.Spec.Ports[i].NodePort = existing.NodePort
+		}
+	}
+}
+
+// createPersesService renders the Service fronting a Perses instance from
+// perses.Spec.Service, falling back to a ClusterIP Service with a single
+// http/8080 port when the user hasn't customized it.
 func (r *PersesReconciler) createPersesService(
 	perses *v1alpha1.Perses) (*corev1.Service, error) {
 	ls, err := common.LabelsForPerses(r.Config.PersesImage, perses.Name, perses)
@@ -113,6 +158,20 @@ func (r *PersesReconciler) createPersesService(
 		annotations = perses.Spec.Metadata.Annotations
 	}
 
+	spec, err := buildServiceSpec(perses.Spec.Service, ls)
+	if err != nil {
+		return nil, err
+	}
+
+	if perses.Spec.TLSEnabled() {
+		spec.Ports = append(spec.Ports, corev1.ServicePort{
+			Name:       common.PersesServiceTLSPortName,
+			Port:       common.PersesContainerTLSPort,
+			Protocol:   corev1.ProtocolTCP,
+			TargetPort: intstr.FromInt32(common.PersesContainerTLSPort),
+		})
+	}
+
 	ser := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        perses.Name,
@@ -120,16 +179,7 @@ func (r *PersesReconciler) createPersesService(
 			Annotations: annotations,
 			Labels:      ls,
 		},
-		Spec: corev1.ServiceSpec{
-			Type: corev1.ServiceTypeClusterIP,
-			Ports: []corev1.ServicePort{{
-				Name:       "http",
-				Port:       8080,
-				Protocol:   corev1.ProtocolTCP,
-				TargetPort: intstr.FromInt32(8080),
-			}},
-			Selector: ls,
-		},
+		Spec: spec,
 	}
 
 	// Set the ownerRef for the Service
@@ -139,3 +189,79 @@ func (r *PersesReconciler) createPersesService(
 	}
 	return ser, nil
 }
+
+// defaultServicePort is used whenever the user hasn't customized
+// Spec.Service.Ports at all.
+var defaultServicePort = corev1.ServicePort{
+	Name:       common.PersesServicePortName,
+	Port:       common.PersesContainerPort,
+	Protocol:   corev1.ProtocolTCP,
+	TargetPort: intstr.FromInt32(common.PersesContainerPort),
+}
+
+// buildServiceSpec translates a v1alpha1.ServiceSpec (or nil, for the
+// default) into a corev1.ServiceSpec, validating that at least one port
+// targets the Perses HTTP container port.
+func buildServiceSpec(spec *v1alpha1.ServiceSpec, selector map[string]string) (corev1.ServiceSpec, error) {
+	if spec == nil {
+		return corev1.ServiceSpec{
+			Type:     corev1.ServiceTypeClusterIP,
+			Ports:    []corev1.ServicePort{defaultServicePort},
+			Selector: selector,
+		}, nil
+	}
+
+	svcType := spec.Type
+	if svcType == "" {
+		svcType = corev1.ServiceTypeClusterIP
+	}
+
+	ports := make([]corev1.ServicePort, 0, len(spec.Ports))
+	targetsContainerPort := false
+	for _, p := range spec.Ports {
+		port := corev1.ServicePort{
+			Name:        p.Name,
+			Port:        p.Port,
+			Protocol:    p.Protocol,
+			TargetPort:  p.TargetPort,
+			AppProtocol: p.AppProtocol,
+			NodePort:    p.NodePort,
+		}
+		if port.Protocol == "" {
+			port.Protocol = corev1.ProtocolTCP
+		}
+		if port.TargetPort.IntValue() == 0 && port.TargetPort.StrVal == "" {
+			port.TargetPort = intstr.FromInt32(port.Port)
+		}
+		if port.TargetPort.IntValue() == common.PersesContainerPort {
+			targetsContainerPort = true
+		}
+		ports = append(ports, port)
+	}
+	if len(ports) == 0 {
+		ports = []corev1.ServicePort{defaultServicePort}
+		targetsContainerPort = true
+	}
+	if !targetsContainerPort {
+		return corev1.ServiceSpec{}, fmt.Errorf(
+			"perses.spec.service.ports must include a port targeting the Perses container port (%d)",
+			common.PersesContainerPort)
+	}
+
+	ser := corev1.ServiceSpec{
+		Type:                     svcType,
+		Ports:                    ports,
+		Selector:                 selector,
+		LoadBalancerIP:           spec.LoadBalancerIP,
+		LoadBalancerSourceRanges: spec.LoadBalancerSourceRanges,
+		ExternalTrafficPolicy:    spec.ExternalTrafficPolicy,
+		SessionAffinity:          spec.SessionAffinity,
+		IPFamilyPolicy:           spec.IPFamilyPolicy,
+	}
+
+	if svcType == corev1.ServiceTypeClusterIP && spec.HeadlessService {
+		ser.ClusterIP = corev1.ClusterIPNone
+	}
+
+	return ser, nil
+}
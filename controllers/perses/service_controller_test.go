@@ -0,0 +1,66 @@
+/*
+Copyright 2023 The Perses Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package perses
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/perses/perses-operator/api/v1alpha1"
+)
+
+var _ = Describe("Perses controller", func() {
+	Context("When a Perses instance's Service is deleted out-of-band", func() {
+		It("recreates the Service on the next reconcile without touching the parent CR", func() {
+			name := "self-heal-service"
+			namespace := "default"
+			lookupKey := types.NamespacedName{Name: name, Namespace: namespace}
+			req := ctrl.Request{NamespacedName: lookupKey}
+
+			perses := &v1alpha1.Perses{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			}
+			Expect(k8sClient.Create(ctx, perses)).To(Succeed())
+
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			createdSvc := &corev1.Service{}
+			Expect(k8sClient.Get(ctx, lookupKey, createdSvc)).To(Succeed())
+
+			Expect(k8sClient.Delete(ctx, createdSvc)).To(Succeed())
+			Expect(k8sClient.Get(ctx, lookupKey, &corev1.Service{})).NotTo(Succeed())
+
+			// Owns(&corev1.Service{}) in SetupWithManager is what makes a
+			// real manager re-trigger this Reconcile the moment the Service
+			// is deleted; calling it here stands in for that watch event.
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, lookupKey, &corev1.Service{})).To(Succeed())
+
+			unchangedPerses := &v1alpha1.Perses{}
+			Expect(k8sClient.Get(ctx, lookupKey, unchangedPerses)).To(Succeed())
+			Expect(unchangedPerses.Generation).To(Equal(perses.Generation))
+		})
+	})
+})
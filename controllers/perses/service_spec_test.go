@@ -0,0 +1,166 @@
+/*
+Copyright 2023 The Perses Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package perses
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/perses/perses-operator/api/v1alpha1"
+	"github.com/perses/perses-operator/internal/perses/common"
+)
+
+func TestBuildServiceSpec(t *testing.T) {
+	selector := map[string]string{"app.kubernetes.io/instance": "test"}
+
+	t.Run("nil spec defaults to ClusterIP with the container port", func(t *testing.T) {
+		spec, err := buildServiceSpec(nil, selector)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if spec.Type != corev1.ServiceTypeClusterIP {
+			t.Errorf("Type = %v, want ClusterIP", spec.Type)
+		}
+		if len(spec.Ports) != 1 || spec.Ports[0].Port != common.PersesContainerPort {
+			t.Errorf("Ports = %+v, want a single port on %d", spec.Ports, common.PersesContainerPort)
+		}
+	})
+
+	t.Run("rejects ports that never target the container port", func(t *testing.T) {
+		_, err := buildServiceSpec(&v1alpha1.ServiceSpec{
+			Ports: []v1alpha1.ServicePort{{Name: "metrics", Port: 9090}},
+		}, selector)
+		if err == nil {
+			t.Fatal("expected an error, got nil")
+		}
+	})
+
+	t.Run("accepts a custom port explicitly targeting the container port", func(t *testing.T) {
+		spec, err := buildServiceSpec(&v1alpha1.ServiceSpec{
+			Ports: []v1alpha1.ServicePort{{Name: "web", Port: 80, TargetPort: intstr.FromInt32(common.PersesContainerPort)}},
+		}, selector)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(spec.Ports) != 1 || spec.Ports[0].TargetPort.IntValue() != common.PersesContainerPort {
+			t.Errorf("Ports = %+v, want TargetPort %d", spec.Ports, common.PersesContainerPort)
+		}
+	})
+
+	t.Run("NodePort type is passed through with the requested port's NodePort", func(t *testing.T) {
+		spec, err := buildServiceSpec(&v1alpha1.ServiceSpec{
+			Type:  corev1.ServiceTypeNodePort,
+			Ports: []v1alpha1.ServicePort{{Name: "http", Port: common.PersesContainerPort, NodePort: 30080}},
+		}, selector)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if spec.Type != corev1.ServiceTypeNodePort {
+			t.Errorf("Type = %v, want NodePort", spec.Type)
+		}
+		if spec.Ports[0].NodePort != 30080 {
+			t.Errorf("NodePort = %d, want 30080", spec.Ports[0].NodePort)
+		}
+	})
+
+	t.Run("headless requests ClusterIP: None", func(t *testing.T) {
+		spec, err := buildServiceSpec(&v1alpha1.ServiceSpec{
+			HeadlessService: true,
+			Ports:           []v1alpha1.ServicePort{{Name: "http", Port: common.PersesContainerPort}},
+		}, selector)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if spec.ClusterIP != corev1.ClusterIPNone {
+			t.Errorf("ClusterIP = %q, want %q", spec.ClusterIP, corev1.ClusterIPNone)
+		}
+	})
+
+	t.Run("headless is ignored outside of ClusterIP", func(t *testing.T) {
+		spec, err := buildServiceSpec(&v1alpha1.ServiceSpec{
+			Type:            corev1.ServiceTypeLoadBalancer,
+			HeadlessService: true,
+			Ports:           []v1alpha1.ServicePort{{Name: "http", Port: common.PersesContainerPort}},
+		}, selector)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if spec.ClusterIP == corev1.ClusterIPNone {
+			t.Errorf("ClusterIP = %q, want unset for a LoadBalancer Service", spec.ClusterIP)
+		}
+	})
+}
+
+func TestPreserveClusterAssignedFields(t *testing.T) {
+	t.Run("copies the assigned ClusterIP and NodePort onto the desired Service", func(t *testing.T) {
+		found := &corev1.Service{Spec: corev1.ServiceSpec{
+			Type:      corev1.ServiceTypeNodePort,
+			ClusterIP: "10.0.0.5",
+			Ports:     []corev1.ServicePort{{Name: "http", NodePort: 30080}},
+		}}
+		desired := &corev1.Service{Spec: corev1.ServiceSpec{
+			Type:  corev1.ServiceTypeNodePort,
+			Ports: []corev1.ServicePort{{Name: "http"}},
+		}}
+
+		preserveClusterAssignedFields(found, desired)
+
+		if desired.Spec.ClusterIP != "10.0.0.5" {
+			t.Errorf("ClusterIP = %q, want %q", desired.Spec.ClusterIP, "10.0.0.5")
+		}
+		if desired.Spec.Ports[0].NodePort != 30080 {
+			t.Errorf("NodePort = %d, want 30080", desired.Spec.Ports[0].NodePort)
+		}
+	})
+
+	t.Run("does not revert a Service the user just switched to headless", func(t *testing.T) {
+		found := &corev1.Service{Spec: corev1.ServiceSpec{
+			Type:       corev1.ServiceTypeClusterIP,
+			ClusterIP:  "10.0.0.5",
+			ClusterIPs: []string{"10.0.0.5"},
+		}}
+		desired := &corev1.Service{Spec: corev1.ServiceSpec{
+			Type:      corev1.ServiceTypeClusterIP,
+			ClusterIP: corev1.ClusterIPNone,
+		}}
+
+		preserveClusterAssignedFields(found, desired)
+
+		if desired.Spec.ClusterIP != corev1.ClusterIPNone {
+			t.Errorf("ClusterIP = %q, want %q to stick", desired.Spec.ClusterIP, corev1.ClusterIPNone)
+		}
+		if desired.Spec.ClusterIPs != nil {
+			t.Errorf("ClusterIPs = %v, want nil to stick", desired.Spec.ClusterIPs)
+		}
+	})
+
+	t.Run("ExternalName Services never get a ClusterIP copied in", func(t *testing.T) {
+		found := &corev1.Service{Spec: corev1.ServiceSpec{
+			Type:      corev1.ServiceTypeExternalName,
+			ClusterIP: "10.0.0.5",
+		}}
+		desired := &corev1.Service{Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeExternalName}}
+
+		preserveClusterAssignedFields(found, desired)
+
+		if desired.Spec.ClusterIP != "" {
+			t.Errorf("ClusterIP = %q, want empty for ExternalName", desired.Spec.ClusterIP)
+		}
+	})
+}
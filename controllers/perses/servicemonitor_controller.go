@@ -0,0 +1,147 @@
+/*
+Copyright 2023 The Perses Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package perses
+
+import (
+	"context"
+
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/perses/perses-operator/api/v1alpha1"
+	"github.com/perses/perses-operator/internal/perses/common"
+	"github.com/perses/perses-operator/internal/subreconciler"
+)
+
+// serviceMonitorGroupKind is looked up against the RESTMapper to detect
+// whether the monitoring.coreos.com ServiceMonitor CRD is installed,
+// without ever having to hard-depend on Prometheus Operator being present.
+var serviceMonitorGroupKind = schema.GroupKind{Group: monitoringv1.SchemeGroupVersion.Group, Kind: monitoringv1.ServiceMonitorsKind}
+
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors,verbs=get;list;watch;create;update;patch;delete
+
+// reconcileServiceMonitor creates or updates the ServiceMonitor for a
+// Perses instance based on Spec.Monitoring.ServiceMonitor. It is a no-op
+// (logged on each reconcile) when the ServiceMonitor CRD isn't installed in
+// the cluster.
+func (r *PersesReconciler) reconcileServiceMonitor(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	perses := &v1alpha1.Perses{}
+
+	if result, err := r.getLatestPerses(ctx, req, perses); subreconciler.ShouldHaltOrRequeue(result, err) {
+		return result, err
+	}
+
+	log := r.Log.WithValues("perses", perses.Name, "namespace", perses.Namespace, "reconciler", "servicemonitor")
+
+	smSpec := perses.Spec.Monitoring.GetServiceMonitor()
+	if smSpec == nil || !smSpec.Enabled {
+		return subreconciler.ContinueReconciling()
+	}
+
+	if _, err := r.RESTMapper().RESTMapping(serviceMonitorGroupKind, monitoringv1.SchemeGroupVersion.Version); err != nil {
+		if meta.IsNoMatchError(err) {
+			log.Info("Spec.Monitoring.ServiceMonitor is enabled but the ServiceMonitor CRD is not installed; skipping")
+			return subreconciler.ContinueReconciling()
+		}
+
+		log.Error(err, "Failed to check for the ServiceMonitor CRD")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	sm, err := r.createPersesServiceMonitor(perses, smSpec)
+	if err != nil {
+		log.Error(err, "Failed to define ServiceMonitor resource for perses")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	found := &monitoringv1.ServiceMonitor{}
+	if err := r.Get(ctx, types.NamespacedName{Name: sm.Name, Namespace: sm.Namespace}, found); err != nil {
+		if !apierrors.IsNotFound(err) {
+			log.Error(err, "Failed to get ServiceMonitor")
+			return subreconciler.RequeueWithError(err)
+		}
+
+		log.Info("Creating a new ServiceMonitor", "ServiceMonitor.Namespace", sm.Namespace, "ServiceMonitor.Name", sm.Name)
+		if err := r.Create(ctx, sm); err != nil {
+			log.Error(err, "Failed to create new ServiceMonitor")
+			return subreconciler.RequeueWithError(err)
+		}
+
+		return subreconciler.ContinueReconciling()
+	}
+
+	sm.ResourceVersion = found.ResourceVersion
+	if err := r.Update(ctx, sm); err != nil {
+		log.Error(err, "Failed to update ServiceMonitor")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	return subreconciler.ContinueReconciling()
+}
+
+// createPersesServiceMonitor renders the ServiceMonitor selecting the
+// Service produced by createPersesService.
+func (r *PersesReconciler) createPersesServiceMonitor(
+	perses *v1alpha1.Perses, smSpec *v1alpha1.ServiceMonitorSpec) (*monitoringv1.ServiceMonitor, error) {
+	ls, err := common.LabelsForPerses(r.Config.PersesImage, perses.Name, perses)
+	if err != nil {
+		return nil, err
+	}
+
+	// Match the port names createPersesService puts on the Service: it
+	// appends the TLS port alongside the plain one only when TLS is enabled,
+	// and the TLS port is scraped in that case.
+	port := common.PersesServicePortName
+	if perses.Spec.TLSEnabled() {
+		port = common.PersesServiceTLSPortName
+	}
+
+	endpoint := monitoringv1.Endpoint{
+		Port:           port,
+		Interval:       smSpec.Interval,
+		ScrapeTimeout:  smSpec.ScrapeTimeout,
+		RelabelConfigs: smSpec.RelabelConfigs,
+	}
+	if smSpec.TLSConfig != nil {
+		endpoint.TLSConfig = smSpec.TLSConfig
+	}
+	if smSpec.BearerTokenSecret != nil {
+		endpoint.BearerTokenSecret = *smSpec.BearerTokenSecret
+	}
+
+	sm := &monitoringv1.ServiceMonitor{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      perses.Name,
+			Namespace: perses.Namespace,
+			Labels:    ls,
+		},
+		Spec: monitoringv1.ServiceMonitorSpec{
+			Selector:  metav1.LabelSelector{MatchLabels: ls},
+			Endpoints: []monitoringv1.Endpoint{endpoint},
+		},
+	}
+
+	if err := ctrl.SetControllerReference(perses, sm, r.Scheme); err != nil {
+		return nil, err
+	}
+	return sm, nil
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2023 The Perses Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package perses
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/perses/perses-operator/api/v1alpha1"
+)
+
+var (
+	k8sClient  client.Client
+	reconciler *PersesReconciler
+	ctx        context.Context
+)
+
+func TestControllers(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Perses Controller Suite")
+}
+
+// This suite exercises PersesReconciler against a fake client rather than
+// envtest: the repo has no kubebuilder scaffold (no config/crd/bases to
+// install), so there are no real CRDs for envtest to start against. Tests
+// call reconciler.Reconcile directly instead of relying on a running
+// manager's watches to re-trigger it.
+var _ = BeforeSuite(func() {
+	logf.SetLogger(zap.New(zap.WriteTo(GinkgoWriter), zap.UseDevMode(true)))
+
+	ctx = context.Background()
+
+	scheme := runtime.NewScheme()
+	Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+
+	k8sClient = fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&v1alpha1.Perses{}).
+		Build()
+
+	reconciler = &PersesReconciler{
+		Client: k8sClient,
+		Scheme: scheme,
+		Config: OperatorConfig{PersesImage: "persesdev/perses:latest"},
+		Log:    ctrl.Log.WithName("controllers").WithName("Perses"),
+	}
+})
@@ -0,0 +1,196 @@
+/*
+Copyright 2023 The Perses Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package perses
+
+import (
+	"context"
+	"fmt"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/perses/perses-operator/api/v1alpha1"
+	"github.com/perses/perses-operator/internal/perses/common"
+	"github.com/perses/perses-operator/internal/subreconciler"
+)
+
+// certificateGroupKind is looked up against the RESTMapper to detect
+// whether the cert-manager Certificate CRD is installed.
+var certificateGroupKind = schema.GroupKind{Group: cmapi.SchemeGroupVersion.Group, Kind: cmapi.CertificateKind}
+
+// +kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;create;update;patch;delete
+
+// reconcileTLS satisfies Spec.TLS: when CertManagerIssuerRef is set it
+// requests/renews a cert-manager Certificate targeting SecretName and
+// waits for it to become Ready; otherwise it validates that a
+// user-provided SecretName already carries a usable tls.crt/tls.key pair.
+// Either way it surfaces the result as the TypeTLSReady status condition,
+// except when the cert-manager Certificate CRD is not installed: then it
+// logs and moves on without setting a condition.
+//
+// It does not mount the Secret into the Perses Deployment, switch the
+// container's listen config to HTTPS, apply TLSSpec.MinVersion or set an
+// Ingress TLS stanza; on the Service side, createPersesService opens the
+// https/8443 port.
+func (r *PersesReconciler) reconcileTLS(ctx context.Context, req ctrl.Request) (*ctrl.Result, error) {
+	perses := &v1alpha1.Perses{}
+
+	if result, err := r.getLatestPerses(ctx, req, perses); subreconciler.ShouldHaltOrRequeue(result, err) {
+		return result, err
+	}
+
+	log := r.Log.WithValues("perses", perses.Name, "namespace", perses.Namespace, "reconciler", "tls")
+
+	if !perses.Spec.TLSEnabled() {
+		return subreconciler.ContinueReconciling()
+	}
+	tlsSpec := perses.Spec.TLS
+
+	if tlsSpec.CertManagerIssuerRef == nil {
+		return r.reconcileExistingTLSSecret(ctx, perses, tlsSpec, log)
+	}
+
+	return r.reconcileCertManagerCertificate(ctx, perses, tlsSpec, log)
+}
+
+// reconcileExistingTLSSecret handles the bring-your-own-certificate path:
+// it only validates that SecretName carries the keys a TLS listener needs.
+func (r *PersesReconciler) reconcileExistingTLSSecret(ctx context.Context, perses *v1alpha1.Perses, tlsSpec *v1alpha1.TLSSpec, log logr.Logger) (*ctrl.Result, error) {
+	secret := &corev1.Secret{}
+	err := r.Get(ctx, types.NamespacedName{Name: tlsSpec.SecretName, Namespace: perses.Namespace}, secret)
+
+	condition := metav1.Condition{Type: common.TypeTLSReady, Status: metav1.ConditionTrue, Reason: "SecretValid",
+		Message: fmt.Sprintf("TLS Secret %s is present and valid", tlsSpec.SecretName)}
+
+	switch {
+	case apierrors.IsNotFound(err):
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "SecretNotFound"
+		condition.Message = fmt.Sprintf("TLS Secret %s does not exist", tlsSpec.SecretName)
+	case err != nil:
+		log.Error(err, "Failed to get TLS Secret")
+		return subreconciler.RequeueWithError(err)
+	case secret.Data[corev1.TLSCertKey] == nil || secret.Data[corev1.TLSPrivateKeyKey] == nil:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "SecretMissingKeys"
+		condition.Message = fmt.Sprintf("TLS Secret %s is missing %s or %s", tlsSpec.SecretName, corev1.TLSCertKey, corev1.TLSPrivateKeyKey)
+	}
+
+	meta.SetStatusCondition(&perses.Status.Conditions, condition)
+	if statusErr := r.Status().Update(ctx, perses); statusErr != nil {
+		log.Error(statusErr, "Failed to update perses status")
+		return subreconciler.RequeueWithError(statusErr)
+	}
+
+	if condition.Status != metav1.ConditionTrue {
+		return subreconciler.Requeue()
+	}
+	return subreconciler.ContinueReconciling()
+}
+
+// reconcileCertManagerCertificate requests SecretName from cert-manager via
+// tlsSpec.CertManagerIssuerRef and waits for it to become Ready.
+func (r *PersesReconciler) reconcileCertManagerCertificate(ctx context.Context, perses *v1alpha1.Perses, tlsSpec *v1alpha1.TLSSpec, log logr.Logger) (*ctrl.Result, error) {
+	if _, err := r.RESTMapper().RESTMapping(certificateGroupKind, cmapi.SchemeGroupVersion.Version); err != nil {
+		if meta.IsNoMatchError(err) {
+			log.Info("Spec.TLS.CertManagerIssuerRef is set but the cert-manager Certificate CRD is not installed; skipping")
+			return subreconciler.ContinueReconciling()
+		}
+
+		log.Error(err, "Failed to check for the Certificate CRD")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	desired := &cmapi.Certificate{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      perses.Name,
+			Namespace: perses.Namespace,
+		},
+		Spec: cmapi.CertificateSpec{
+			SecretName: tlsSpec.SecretName,
+			DNSNames:   []string{fmt.Sprintf("%s.%s.svc", perses.Name, perses.Namespace)},
+			IssuerRef: cmmeta.ObjectReference{
+				Name:  tlsSpec.CertManagerIssuerRef.Name,
+				Kind:  tlsSpec.CertManagerIssuerRef.Kind,
+				Group: tlsSpec.CertManagerIssuerRef.Group,
+			},
+		},
+	}
+	if err := ctrl.SetControllerReference(perses, desired, r.Scheme); err != nil {
+		return subreconciler.RequeueWithError(err)
+	}
+
+	found := &cmapi.Certificate{}
+	if err := r.Get(ctx, types.NamespacedName{Name: desired.Name, Namespace: desired.Namespace}, found); err != nil {
+		if !apierrors.IsNotFound(err) {
+			log.Error(err, "Failed to get Certificate")
+			return subreconciler.RequeueWithError(err)
+		}
+
+		log.Info("Creating a new Certificate", "Certificate.Namespace", desired.Namespace, "Certificate.Name", desired.Name)
+		if err := r.Create(ctx, desired); err != nil {
+			log.Error(err, "Failed to create new Certificate")
+			return subreconciler.RequeueWithError(err)
+		}
+
+		return r.setTLSReadyCondition(ctx, perses, false, "CertificateRequested", "Waiting for cert-manager to issue the certificate", log)
+	}
+
+	desired.ResourceVersion = found.ResourceVersion
+	if err := r.Update(ctx, desired); err != nil {
+		log.Error(err, "Failed to update Certificate")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	for _, c := range found.Status.Conditions {
+		if c.Type == cmapi.CertificateConditionReady && c.Status == cmmeta.ConditionTrue {
+			return r.setTLSReadyCondition(ctx, perses, true, "CertificateReady", "cert-manager Certificate is Ready", log)
+		}
+	}
+
+	return r.setTLSReadyCondition(ctx, perses, false, "CertificateNotReady", "Waiting for cert-manager Certificate to become Ready", log)
+}
+
+// setTLSReadyCondition records TypeTLSReady on perses.Status and requeues
+// until it flips true.
+func (r *PersesReconciler) setTLSReadyCondition(ctx context.Context, perses *v1alpha1.Perses, ready bool, reason, message string, log logr.Logger) (*ctrl.Result, error) {
+	status := metav1.ConditionFalse
+	if ready {
+		status = metav1.ConditionTrue
+	}
+
+	meta.SetStatusCondition(&perses.Status.Conditions, metav1.Condition{
+		Type: common.TypeTLSReady, Status: status, Reason: reason, Message: message,
+	})
+	if err := r.Status().Update(ctx, perses); err != nil {
+		log.Error(err, "Failed to update perses status")
+		return subreconciler.RequeueWithError(err)
+	}
+
+	if !ready {
+		return subreconciler.Requeue()
+	}
+	return subreconciler.ContinueReconciling()
+}
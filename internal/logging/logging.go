@@ -0,0 +1,49 @@
+/*
+Copyright 2023 The Perses Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package logging builds the operator's root logr.Logger, backed by zap.
+package logging
+
+import (
+	"os"
+
+	"github.com/go-logr/logr"
+	"go.uber.org/zap/zapcore"
+	ctrlzap "sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+// operatorLoggingEnvVar selects the encoder/level used by New, so the same
+// binary can run with human-readable console logs in dev and structured
+// JSON logs everywhere else.
+const operatorLoggingEnvVar = "OPERATOR_LOGGING"
+
+// New builds the operator's root logger. OPERATOR_LOGGING controls it:
+//   - unset or "dev": human-readable console encoder, debug level
+//   - "debug", "info", "error": JSON encoder at the given level
+//
+// Any other value falls back to JSON at info level.
+func New() logr.Logger {
+	switch os.Getenv(operatorLoggingEnvVar) {
+	case "", "dev":
+		return ctrlzap.New(ctrlzap.UseDevMode(true))
+	case "debug":
+		return ctrlzap.New(ctrlzap.Level(zapcore.DebugLevel))
+	case "error":
+		return ctrlzap.New(ctrlzap.Level(zapcore.ErrorLevel))
+	default:
+		return ctrlzap.New(ctrlzap.Level(zapcore.InfoLevel))
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2023 The Perses Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package common holds helpers shared across the perses subreconcilers.
+package common
+
+import (
+	"github.com/perses/perses-operator/api/v1alpha1"
+)
+
+const (
+	// TypeAvailablePerses is the status condition type reported once the
+	// Perses deployment is available and serving traffic.
+	TypeAvailablePerses = "Available"
+
+	// TypeDegradedPerses is the status condition type reported when a
+	// reconciler could not bring a child resource to the desired state.
+	TypeDegradedPerses = "Degraded"
+
+	// TypeTLSReady is the status condition type reported once Spec.TLS's
+	// Secret is valid and ready to be mounted/served.
+	TypeTLSReady = "TLSReady"
+)
+
+// PersesContainerPort is the port the Perses HTTP server listens on inside
+// the container image.
+const PersesContainerPort = 8080
+
+// PersesContainerTLSPort is the port the Perses HTTPS listener binds to
+// inside the container image once Spec.TLS is enabled.
+const PersesContainerTLSPort = 8443
+
+// PersesServicePortName and PersesServiceTLSPortName are the Service port
+// names createPersesService assigns to the plain and TLS listeners. Anything
+// that needs to target one of these ports by name (e.g. a ServiceMonitor
+// Endpoint) should reference these rather than the string literals, so the
+// two stay in lockstep.
+const (
+	PersesServicePortName    = "http"
+	PersesServiceTLSPortName = "https"
+)
+
+// LabelsForPerses returns the standard set of labels applied to every
+// resource owned by a Perses instance, merged with any user-supplied
+// labels from perses.Spec.Metadata.
+func LabelsForPerses(image string, name string, perses *v1alpha1.Perses) (map[string]string, error) {
+	labels := map[string]string{
+		"app.kubernetes.io/name":       "perses",
+		"app.kubernetes.io/instance":   name,
+		"app.kubernetes.io/part-of":    "perses-operator",
+		"app.kubernetes.io/managed-by": "perses-operator",
+	}
+
+	if image != "" {
+		labels["app.kubernetes.io/version"] = image
+	}
+
+	if perses != nil && perses.Spec.Metadata != nil {
+		for k, v := range perses.Spec.Metadata.Labels {
+			labels[k] = v
+		}
+	}
+
+	return labels, nil
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2023 The Perses Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics manages the operator's own metrics Service, independently
+// of any Perses custom resource, so that `kubectl delete` on a Perses CR
+// (or the CR simply not existing yet) never interrupts Prometheus scraping
+// of the controller-runtime /metrics endpoint.
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// ServiceName is the name of the operator-wide metrics Service.
+const ServiceName = "perses-operator-metrics"
+
+const metricsPortName = "metrics"
+
+// ReconcileInterval is how often RunOperatorMetrics re-asserts the
+// operator-metrics Service/ServiceMonitor, so a `kubectl delete` on either
+// one self-heals without requiring the operator process to restart.
+const ReconcileInterval = 5 * time.Minute
+
+var serviceMonitorGroupKind = schema.GroupKind{Group: monitoringv1.SchemeGroupVersion.Group, Kind: monitoringv1.ServiceMonitorsKind}
+
+// EnsureOperatorMetricsService creates or updates the perses-operator-metrics
+// Service exposing the controller-runtime /metrics endpoint for the
+// operator deployment matching selector, in namespace. Called repeatedly by
+// RunOperatorMetrics rather than as part of any Perses reconcile loop.
+func EnsureOperatorMetricsService(ctx context.Context, c client.Client, namespace string, selector map[string]string, metricsPort int32) error {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ServiceName,
+			Namespace: namespace,
+			Labels:    selector,
+		},
+		Spec: corev1.ServiceSpec{
+			Type:     corev1.ServiceTypeClusterIP,
+			Selector: selector,
+			Ports: []corev1.ServicePort{{
+				Name:       metricsPortName,
+				Port:       metricsPort,
+				Protocol:   corev1.ProtocolTCP,
+				TargetPort: intstr.FromInt32(metricsPort),
+			}},
+		},
+	}
+
+	found := &corev1.Service{}
+	err := c.Get(ctx, types.NamespacedName{Name: svc.Name, Namespace: svc.Namespace}, found)
+	switch {
+	case apierrors.IsNotFound(err):
+		return c.Create(ctx, svc)
+	case err != nil:
+		return err
+	default:
+		svc.ResourceVersion = found.ResourceVersion
+		svc.Spec.ClusterIP = found.Spec.ClusterIP
+		svc.Spec.ClusterIPs = found.Spec.ClusterIPs
+		return c.Update(ctx, svc)
+	}
+}
+
+// EnsureOperatorServiceMonitor creates or updates a ServiceMonitor for the
+// operator-metrics Service when the ServiceMonitor CRD is installed in the
+// cluster, and is a no-op otherwise.
+func EnsureOperatorServiceMonitor(ctx context.Context, c client.Client, namespace string, selector map[string]string) error {
+	if _, err := c.RESTMapper().RESTMapping(serviceMonitorGroupKind, monitoringv1.SchemeGroupVersion.Version); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil
+		}
+		return err
+	}
+
+	sm := &monitoringv1.ServiceMonitor{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ServiceName,
+			Namespace: namespace,
+			Labels:    selector,
+		},
+		Spec: monitoringv1.ServiceMonitorSpec{
+			Selector:  metav1.LabelSelector{MatchLabels: selector},
+			Endpoints: []monitoringv1.Endpoint{{Port: metricsPortName}},
+		},
+	}
+
+	found := &monitoringv1.ServiceMonitor{}
+	err := c.Get(ctx, types.NamespacedName{Name: sm.Name, Namespace: sm.Namespace}, found)
+	switch {
+	case apierrors.IsNotFound(err):
+		return c.Create(ctx, sm)
+	case err != nil:
+		return err
+	default:
+		sm.ResourceVersion = found.ResourceVersion
+		return c.Update(ctx, sm)
+	}
+}
+
+// RunOperatorMetrics returns a manager.Runnable that (re)asserts the
+// operator-metrics Service and ServiceMonitor every ReconcileInterval, using
+// c directly rather than a manager's cached client. Plugging this in via
+// mgr.Add means it starts running only once the manager itself starts, so it
+// never races informer cache sync; using an uncached client rather than
+// mgr.GetClient() means it still wouldn't block waiting on that cache even
+// if it ran earlier. The recurring reconcile is what makes a manual
+// `kubectl delete` on either resource self-heal instead of requiring an
+// operator restart, matching the per-instance Service's Owns()-driven
+// self-heal.
+func RunOperatorMetrics(c client.Client, namespace string, selector map[string]string, metricsPort int32, log logr.Logger) manager.Runnable {
+	return manager.RunnableFunc(func(ctx context.Context) error {
+		reconcile := func() {
+			if err := EnsureOperatorMetricsService(ctx, c, namespace, selector, metricsPort); err != nil {
+				log.Error(err, "unable to ensure operator metrics Service")
+				return
+			}
+			if err := EnsureOperatorServiceMonitor(ctx, c, namespace, selector); err != nil {
+				log.Error(err, "unable to ensure operator ServiceMonitor")
+			}
+		}
+
+		reconcile()
+
+		ticker := time.NewTicker(ReconcileInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+				reconcile()
+			}
+		}
+	})
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2023 The Perses Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package subreconciler provides the small set of sentinel results shared by
+// every Perses subreconciler (reconcileService, reconcileServiceMonitor, ...) so
+// they can be chained from a single top-level Reconcile without each one
+// reimplementing requeue/halt semantics.
+package subreconciler
+
+import (
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// requeueDelay is used whenever a subreconciler asks to be requeued without
+// an error (e.g. waiting on a condition to flip).
+const requeueDelay = 5 * time.Second
+
+// ShouldHaltOrRequeue reports whether the caller should stop chaining
+// subreconcilers and immediately return the given result/error to
+// controller-runtime.
+func ShouldHaltOrRequeue(result *ctrl.Result, err error) bool {
+	return result != nil || err != nil
+}
+
+// ContinueReconciling signals that this subreconciler is done and the
+// caller should move on to the next one in the chain.
+func ContinueReconciling() (*ctrl.Result, error) {
+	return nil, nil
+}
+
+// DoNotRequeue signals that reconciliation is complete and no further
+// requeue is necessary.
+func DoNotRequeue() (*ctrl.Result, error) {
+	return &ctrl.Result{}, nil
+}
+
+// Requeue asks controller-runtime to requeue the request after the default
+// delay, without treating this as an error.
+func Requeue() (*ctrl.Result, error) {
+	return &ctrl.Result{RequeueAfter: requeueDelay}, nil
+}
+
+// RequeueWithError asks controller-runtime to requeue the request because
+// of the given error.
+func RequeueWithError(err error) (*ctrl.Result, error) {
+	return &ctrl.Result{}, err
+}